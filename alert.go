@@ -1,30 +1,35 @@
 package tenderduty
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"github.com/PagerDuty/go-pagerduty"
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"log"
-	"net/http"
 	"strings"
 	"sync"
 	"time"
 )
 
+// alertMsg is the provider-agnostic representation of a single alert. It
+// carries both the human-facing content and the per-destination settings
+// needed to deliver it, since each alertMsg is built once in (*Config).alert
+// (or (*Config).escalate) while the chain is locked and then handed off to
+// every enabled Alerter.
 type alertMsg struct {
 	pd   bool
 	disc bool
 	tg   bool
-
-	severity string
-	resolved bool
-	chain    string
-	message  string
-	uniqueId string
-	key      string
+	slk  bool
+	ops  bool
+	hook bool
+	mail bool
+	irc  bool
+
+	severity  string
+	alarmType string
+	resolved  bool
+	chain     string
+	message   string
+	uniqueId  string
+	key       string
 
 	tgChannel  string
 	tgKey      string
@@ -32,45 +37,69 @@ type alertMsg struct {
 
 	discHook     string
 	discMentions string
-}
 
-type notifyDest uint8
+	slackHook     string
+	slackMentions string
 
-const (
-	pd notifyDest = iota
-	tg
-	di
-)
+	opsgenieKey    string
+	opsgenieApiUrl string
+
+	webhookUrl      string
+	webhookTemplate string
+
+	emailTo   []string
+	emailFrom string
+	smtpHost  string
+	smtpPort  int
+	smtpUser  string
+	smtpPass  string
+
+	ircChannels []string
+
+	tmplData      TemplateData
+	discTemplate  string
+	tgTemplate    string
+	slackTemplate string
+}
+
+// Alerter is implemented by every outbound notification provider. Send must
+// honor ctx's deadline so a slow or unreachable destination can't block the
+// alertChan that feeds notifyMux.dispatch.
+type Alerter interface {
+	// Name identifies the provider for logging and for the per-destination
+	// de-dup map in shouldNotify, e.g. "PagerDuty" or "Slack".
+	Name() string
+	// Enabled reports whether msg is flagged for this provider. By default
+	// the flag is set when the provider section is enabled in config and
+	// the chain that raised the alert has it on; a matching AlertRule with
+	// Destinations, or an escalation step, sets it from its own list
+	// instead (see routedTo).
+	Enabled(msg *alertMsg) bool
+	Send(ctx context.Context, msg *alertMsg) error
+}
 
 var (
-	sentPdAlarms = make(map[string]bool)
-	sentTgAlarms = make(map[string]bool)
-	sentDAlarms  = make(map[string]bool)
-	notifyMux    sync.Mutex
+	sentAlarms = make(map[string]map[string]bool)
+	dedupMux   sync.Mutex
 )
 
-func shouldNotify(msg *alertMsg, dest notifyDest) bool {
-	notifyMux.Lock()
-	defer notifyMux.Unlock()
-	var whichMap map[string]bool
-	var service string
-	switch dest {
-	case pd:
-		whichMap = sentPdAlarms
-		service = "PagerDuty"
-	case tg:
-		whichMap = sentTgAlarms
-		service = "Telegram"
-	case di:
-		whichMap = sentDAlarms
-		service = "Discord"
+func shouldNotify(msg *alertMsg, service string) bool {
+	if !msg.resolved && (isSilenced(msg.chain) || isAcked(msg.chain, msg.message)) {
+		return false
+	}
+	dedupMux.Lock()
+	defer dedupMux.Unlock()
+	if sentAlarms[service] == nil {
+		sentAlarms[service] = make(map[string]bool)
 	}
+	whichMap := sentAlarms[service]
 	if whichMap[msg.message] && !msg.resolved {
 		// already sent this alert
 		return false
 	} else if whichMap[msg.message] && msg.resolved {
 		// alarm is cleared
 		delete(whichMap, msg.message)
+		clearAck(msg.chain, msg.message)
 		l(fmt.Sprintf("💜 Resolved     alarm on %s (%s) - notifying %s", msg.chain, msg.message, service))
 		return true
 	}
@@ -79,140 +108,92 @@ func shouldNotify(msg *alertMsg, dest notifyDest) bool {
 	return true
 }
 
-func notifyDiscord(msg *alertMsg) (err error) {
-	if !msg.disc {
-		return nil
-	}
-	if !shouldNotify(msg, di) {
-		return nil
-	}
-	discPost := buildDiscordMessage(msg)
-	client := &http.Client{}
-	data, err := json.MarshalIndent(discPost, "", "  ")
-	if err != nil {
-		l("notify discord:", err)
-		return err
-	}
-
-	req, err := http.NewRequest("POST", msg.discHook, bytes.NewBuffer(data))
-	if err != nil {
-		l("notify discord:", err)
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
+// ackedAlarms and silencedUntil back the /ack and /silence Discord bot
+// commands: an acked alarm is suppressed until it resolves, a silenced
+// chain is suppressed until the mute expires.
+var (
+	ackedAlarms   = make(map[string]map[string]bool) // chain -> message -> acked
+	silencedUntil = make(map[string]time.Time)       // chain -> mute expiry
+	ackMux        sync.RWMutex
+)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		l("notify discord:", err)
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 204 {
-		log.Println(resp)
-		//if resp.Body != nil {
-		//	b, _ := ioutil.ReadAll(resp.Body)
-		//	_ = resp.Body.Close()
-		//	fmt.Println(string(b))
-		//}
-		l("notify discord:", err)
-		return err
+func ackAlarm(chain, alarm string) {
+	ackMux.Lock()
+	defer ackMux.Unlock()
+	if ackedAlarms[chain] == nil {
+		ackedAlarms[chain] = make(map[string]bool)
 	}
-	return nil
+	ackedAlarms[chain][alarm] = true
 }
 
-type DiscordMessage struct {
-	Username  string         `json:"username,omitempty"`
-	AvatarUrl string         `json:"avatar_url,omitempty"`
-	Content   string         `json:"content"`
-	Embeds    []DiscordEmbed `json:"embeds,omitempty"`
+func isAcked(chain, alarm string) bool {
+	ackMux.RLock()
+	defer ackMux.RUnlock()
+	return ackedAlarms[chain][alarm]
 }
 
-type DiscordEmbed struct {
-	Title       string `json:"title,omitempty"`
-	Url         string `json:"url,omitempty"`
-	Description string `json:"description"`
-	Color       uint   `json:"color"`
+func clearAck(chain, alarm string) {
+	ackMux.Lock()
+	defer ackMux.Unlock()
+	delete(ackedAlarms[chain], alarm)
 }
 
-func buildDiscordMessage(msg *alertMsg) *DiscordMessage {
-	prefix := "🚨 ALERT: "
-	if msg.resolved {
-		prefix = "💜 Resolved: "
-	}
-	return &DiscordMessage{
-		Username: "tenderuty",
-		Content:  prefix + msg.chain,
-		Embeds: []DiscordEmbed{{
-			Description: msg.message,
-		}},
-	}
+func silenceChain(chain string, d time.Duration) {
+	ackMux.Lock()
+	defer ackMux.Unlock()
+	silencedUntil[chain] = time.Now().Add(d)
 }
 
-func notifyTg(msg *alertMsg) (err error) {
-	if !msg.tg {
-		return nil
-	}
-	if !shouldNotify(msg, tg) {
-		return nil
-	}
-	//tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	bot, err := tgbotapi.NewBotAPI(msg.tgKey)
-	if err != nil {
-		l("notify telegram:", err)
-		return
-	}
+func isSilenced(chain string) bool {
+	ackMux.RLock()
+	defer ackMux.RUnlock()
+	until, ok := silencedUntil[chain]
+	return ok && time.Now().Before(until)
+}
 
-	prefix := "🚨 ALERT: "
-	if msg.resolved {
-		prefix = "💜 Resolved: "
+// routedTo reports whether provider should receive this alert. A rule
+// with an explicit Destinations list overrides the chain's usual
+// per-provider Alerts.*Alerts flags; a rule with no Destinations, or no
+// matching rule at all, falls back to def.
+func routedTo(provider string, rule *AlertRule, def bool) bool {
+	if rule == nil || len(rule.Destinations) == 0 {
+		return def
 	}
-
-	mc := tgbotapi.NewMessageToChannel(msg.tgChannel, fmt.Sprintf("%s: %s - %s", msg.chain, prefix, msg.message))
-	//mc.ParseMode = "html"
-	_, err = bot.Send(mc)
-	if err != nil {
-		l("telegram send:", err)
+	for _, d := range rule.Destinations {
+		if d == provider {
+			return true
+		}
 	}
-	return err
+	return false
 }
 
-func notifyPagerduty(msg *alertMsg) (err error) {
-	if !msg.pd {
-		return nil
-	}
-	if !shouldNotify(msg, pd) {
-		return nil
+// mentionsFor returns the mention group a matching rule specifies,
+// falling back to def (the chain's usual per-provider mentions, e.g.
+// c.Telegram.Mentions) when rule is nil or sets no Mentions of its own.
+func mentionsFor(rule *AlertRule, def []string) []string {
+	if rule != nil && len(rule.Mentions) > 0 {
+		return rule.Mentions
 	}
-	// key from the example, don't spam their api
-	if msg.key == "aaaaaaaaaaaabbbbbbbbbbbbbcccccccccccc" {
-		l("invalid pagerduty key")
-		return
-	}
-	action := "trigger"
-	if msg.resolved {
-		action = "resolve"
-	}
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	_, err = pagerduty.ManageEventWithContext(ctx, pagerduty.V2Event{
-		RoutingKey: msg.key,
-		Action:     action,
-		DedupKey:   msg.uniqueId,
-		Payload: &pagerduty.V2Payload{
-			Summary:  msg.message,
-			Source:   msg.uniqueId,
-			Severity: msg.severity,
-		},
-	})
-	return
+	return def
 }
 
 var (
 	currentAlarms    = make(map[string]map[string]bool)
 	currentAlarmsMux = sync.RWMutex{}
+
+	// alarmMeta tracks when each active alarm first fired and which
+	// escalation tier it's currently at, so runEscalator knows when an
+	// unacknowledged alarm is due to bump to the next tier.
+	alarmMeta = make(map[string]map[string]*alarmMetaInfo)
 )
 
+type alarmMetaInfo struct {
+	alarmType string
+	uniqueId  string
+	started   time.Time
+	tier      int
+}
+
 func getAlarms(chain string) string {
 	currentAlarmsMux.RLock()
 	defer currentAlarmsMux.RUnlock()
@@ -229,17 +210,29 @@ func getAlarms(chain string) string {
 }
 
 // alert creates a universal alert and pushes it to the alertChan to be delivered to appropriate services
-func (c *Config) alert(chainName, message, severity string, resolved bool, id *string) {
+func (c *Config) alert(chainName, message, alarmType string, resolved bool, id *string) {
 	uniq := c.Chains[chainName].ValAddress
 	if id != nil {
 		uniq = *id
 	}
+	rule := c.resolveRule(chainName, alarmType)
+	severity := "critical"
+	if rule != nil && rule.Severity != "" {
+		severity = rule.Severity
+	}
+
 	c.chainsMux.RLock()
 	a := &alertMsg{
-		pd:           c.Pagerduty.Enabled && c.Chains[chainName].Alerts.PagerdutyAlerts,
-		disc:         c.Discord.Enabled && c.Chains[chainName].Alerts.DiscordAlerts,
-		tg:           c.Telegram.Enabled && c.Chains[chainName].Alerts.TelegramAlerts,
+		pd:           routedTo("PagerDuty", rule, c.Pagerduty.Enabled && c.Chains[chainName].Alerts.PagerdutyAlerts),
+		disc:         routedTo("Discord", rule, c.Discord.Enabled && c.Chains[chainName].Alerts.DiscordAlerts),
+		tg:           routedTo("Telegram", rule, c.Telegram.Enabled && c.Chains[chainName].Alerts.TelegramAlerts),
+		slk:          routedTo("Slack", rule, c.Slack.Enabled && c.Chains[chainName].Alerts.SlackAlerts),
+		ops:          routedTo("Opsgenie", rule, c.Opsgenie.Enabled && c.Chains[chainName].Alerts.OpsgenieAlerts),
+		hook:         routedTo("webhook", rule, c.Webhook.Enabled && c.Chains[chainName].Alerts.WebhookAlerts),
+		mail:         routedTo("email", rule, c.Email.Enabled && c.Chains[chainName].Alerts.EmailAlerts),
+		irc:          routedTo("IRC", rule, c.IRC.Enabled && c.Chains[chainName].Alerts.IrcAlerts),
 		severity:     severity,
+		alarmType:    alarmType,
 		resolved:     resolved,
 		chain:        chainName,
 		message:      message,
@@ -247,10 +240,31 @@ func (c *Config) alert(chainName, message, severity string, resolved bool, id *s
 		key:          c.Pagerduty.ApiKey,
 		tgChannel:    c.Telegram.Channel,
 		tgKey:        c.Telegram.ApiKey,
-		tgMentions:   strings.Join(c.Telegram.Mentions, " "),
+		tgMentions:   strings.Join(mentionsFor(rule, c.Telegram.Mentions), " "),
 		discHook:     c.Discord.Webhook,
-		discMentions: strings.Join(c.Discord.Mentions, " "),
+		discMentions: strings.Join(mentionsFor(rule, c.Discord.Mentions), " "),
+
+		slackHook:     c.Slack.Webhook,
+		slackMentions: strings.Join(mentionsFor(rule, c.Slack.Mentions), " "),
+
+		opsgenieKey:    c.Opsgenie.ApiKey,
+		opsgenieApiUrl: c.Opsgenie.ApiUrl,
+
+		webhookUrl:      c.Webhook.Url,
+		webhookTemplate: c.Webhook.Template,
+
+		emailTo:   c.Email.To,
+		emailFrom: c.Email.From,
+		smtpHost:  c.Email.SmtpHost,
+		smtpPort:  c.Email.SmtpPort,
+		smtpUser:  c.Email.SmtpUser,
+		smtpPass:  c.Email.SmtpPass,
+
+		ircChannels: c.Chains[chainName].Alerts.IrcChannels,
 	}
+
+	c.applyTemplates(a, chainName, severity, message, resolved)
+
 	c.alertChan <- a
 	c.chainsMux.RUnlock()
 	currentAlarmsMux.Lock()
@@ -260,11 +274,18 @@ func (c *Config) alert(chainName, message, severity string, resolved bool, id *s
 	}
 	if resolved && currentAlarms[chainName][message] {
 		delete(currentAlarms[chainName], message)
+		delete(alarmMeta[chainName], message)
 		return
 	} else if resolved {
 		return
 	}
 	currentAlarms[chainName][message] = true
+	if alarmMeta[chainName] == nil {
+		alarmMeta[chainName] = make(map[string]*alarmMetaInfo)
+	}
+	if alarmMeta[chainName][message] == nil {
+		alarmMeta[chainName][message] = &alarmMetaInfo{alarmType: alarmType, uniqueId: uniq, started: time.Now()}
+	}
 }
 
 // watch handles monitoring for missed blocks, stalled chain, node downtime
@@ -282,7 +303,7 @@ func (cc *ChainConfig) watch() {
 				td.alert(
 					cc.name,
 					fmt.Sprintf("no RPC endpoints are working for %s", cc.ChainId),
-					"critical",
+					alarmTypeNoServers,
 					false,
 					&cc.valInfo.Valcons,
 				)
@@ -307,7 +328,7 @@ func (cc *ChainConfig) watch() {
 			td.alert(
 				cc.name,
 				fmt.Sprintf("no RPC endpoints are working for %s", cc.ChainId),
-				"critical",
+				alarmTypeNoServers,
 				false,
 				&cc.valInfo.Valcons,
 			)
@@ -316,7 +337,7 @@ func (cc *ChainConfig) watch() {
 			td.alert(
 				cc.name,
 				fmt.Sprintf("no RPC endpoints are working for %s", cc.ChainId),
-				"critical",
+				alarmTypeNoServers,
 				true,
 				&cc.valInfo.Valcons,
 			)
@@ -330,7 +351,7 @@ func (cc *ChainConfig) watch() {
 			td.alert(
 				cc.name,
 				fmt.Sprintf("stalled: have not seen a new block on %s in %d minutes", cc.ChainId, cc.Alerts.Stalled),
-				"critical",
+				alarmTypeStalled,
 				false,
 				&cc.valInfo.Valcons,
 			)
@@ -339,7 +360,7 @@ func (cc *ChainConfig) watch() {
 			td.alert(
 				cc.name,
 				fmt.Sprintf("stalled: have not seen a new block on %s in %d minutes", cc.ChainId, cc.Alerts.Stalled),
-				"critical",
+				alarmTypeStalled,
 				true,
 				&cc.valInfo.Valcons,
 			)
@@ -354,7 +375,7 @@ func (cc *ChainConfig) watch() {
 			td.alert(
 				cc.name,
 				fmt.Sprintf("%s has missed %d blocks on %s", cc.valInfo.Moniker, cc.Alerts.ConsecutiveMissed, cc.ChainId),
-				"critical",
+				alarmTypeConsecutiveMissed,
 				false,
 				&id,
 			)
@@ -366,7 +387,7 @@ func (cc *ChainConfig) watch() {
 			td.alert(
 				cc.name,
 				fmt.Sprintf("%s has missed %d blocks on %s", cc.valInfo.Moniker, cc.Alerts.ConsecutiveMissed, cc.ChainId),
-				"critical",
+				alarmTypeConsecutiveMissed,
 				true,
 				&id,
 			)
@@ -382,7 +403,7 @@ func (cc *ChainConfig) watch() {
 			td.alert(
 				cc.name,
 				fmt.Sprintf("%s has missed > %d%% of the slashing window's blocks on %s", cc.valInfo.Moniker, cc.Alerts.Window, cc.ChainId),
-				"critical",
+				alarmTypePercentageWindow,
 				false,
 				&id,
 			)
@@ -394,7 +415,7 @@ func (cc *ChainConfig) watch() {
 			td.alert(
 				cc.name,
 				fmt.Sprintf("%s has missed > %d%% of the slashing window's blocks on %s", cc.valInfo.Moniker, cc.Alerts.Window, cc.ChainId),
-				"critical",
+				alarmTypePercentageWindow,
 				false,
 				&id,
 			)
@@ -410,7 +431,7 @@ func (cc *ChainConfig) watch() {
 				td.alert(
 					cc.name,
 					fmt.Sprintf("RPC node %s has been down for > %d minutes on %s", node.Url, td.NodeDownMin, cc.ChainId),
-					"critical",
+					alarmTypeNodeDown,
 					false,
 					&node.Url,
 				)
@@ -421,7 +442,7 @@ func (cc *ChainConfig) watch() {
 				td.alert(
 					cc.name,
 					fmt.Sprintf("RPC node %s has been down for > %d minutes on %s", node.Url, td.NodeDownMin, cc.ChainId),
-					"critical",
+					alarmTypeNodeDown,
 					false,
 					&node.Url,
 				)
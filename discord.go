@@ -0,0 +1,79 @@
+package tenderduty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+func init() {
+	notifyMux.register(discordAlerter{})
+}
+
+type discordAlerter struct{}
+
+func (discordAlerter) Name() string { return "Discord" }
+
+func (discordAlerter) Enabled(msg *alertMsg) bool {
+	return msg.disc
+}
+
+func (discordAlerter) Send(ctx context.Context, msg *alertMsg) (err error) {
+	discPost := buildDiscordMessage(msg)
+	data, err := json.MarshalIndent(discPost, "", "  ")
+	if err != nil {
+		l("notify discord:", err)
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", msg.discHook, bytes.NewBuffer(data))
+	if err != nil {
+		l("notify discord:", err)
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		l("notify discord:", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
+
+type DiscordMessage struct {
+	Username  string         `json:"username,omitempty"`
+	AvatarUrl string         `json:"avatar_url,omitempty"`
+	Content   string         `json:"content"`
+	Embeds    []DiscordEmbed `json:"embeds,omitempty"`
+}
+
+type DiscordEmbed struct {
+	Title       string `json:"title,omitempty"`
+	Url         string `json:"url,omitempty"`
+	Description string `json:"description"`
+	Color       uint   `json:"color"`
+}
+
+func buildDiscordMessage(msg *alertMsg) *DiscordMessage {
+	description, err := renderTemplate(msg.discTemplate, defaultDiscordTemplate, msg.tmplData)
+	if err != nil {
+		l("notify discord: template:", err)
+		description = msg.message
+	}
+	title := "🚨 ALERT: " + msg.chain
+	if msg.resolved {
+		title = "💜 Resolved: " + msg.chain
+	}
+	return &DiscordMessage{
+		Username: "tenderuty",
+		Embeds: []DiscordEmbed{{
+			Title:       title,
+			Description: description,
+			Color:       templateSeverityColor(msg.severity, msg.resolved),
+		}},
+	}
+}
@@ -0,0 +1,401 @@
+package tenderduty
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jpillora/backoff"
+)
+
+// Discord gateway opcodes, see https://discord.com/developers/docs/topics/opcodes-and-status-codes
+const (
+	gatewayOpDispatch       = 0
+	gatewayOpHeartbeat      = 1
+	gatewayOpIdentify       = 2
+	gatewayOpResume         = 6
+	gatewayOpReconnect      = 7
+	gatewayOpInvalidSession = 9
+	gatewayOpHello          = 10
+	gatewayOpHeartbeatAck   = 11
+)
+
+const discordGatewayUrl = "wss://gateway.discord.gg/?v=10&encoding=json"
+const discordApiBase = "https://discord.com/api/v10"
+
+type gatewayPayload struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d,omitempty"`
+	S  int             `json:"s,omitempty"`
+	T  string          `json:"t,omitempty"`
+}
+
+type gatewayHello struct {
+	HeartbeatInterval int `json:"heartbeat_interval"`
+}
+
+type gatewayReady struct {
+	SessionId string `json:"session_id"`
+}
+
+// discordBot is a minimal Discord gateway client: it keeps a heartbeat
+// alive, resumes on a dropped connection with jittered backoff, and
+// dispatches slash-command interactions. It's only started when
+// Config.Discord.Bot.Enabled is set; otherwise alerts keep going out via
+// the plain incoming-webhook path in discord.go.
+type discordBot struct {
+	token   string
+	guildId string
+
+	mu            sync.Mutex
+	conn          *websocket.Conn
+	seq           atomic.Int64
+	sessionId     string
+	resumeGateway string
+	backoff       *backoff.Backoff
+
+	restLimits *routeLimiter
+}
+
+func newDiscordBot(token, guildId string) *discordBot {
+	return &discordBot{
+		token:   token,
+		guildId: guildId,
+		backoff: &backoff.Backoff{
+			Min:    time.Second,
+			Max:    time.Minute,
+			Factor: 2,
+			Jitter: true,
+		},
+		restLimits: newRouteLimiter(),
+	}
+}
+
+// run connects to the gateway and reconnects/resumes with backoff until
+// ctx is cancelled.
+func (b *discordBot) run(ctx context.Context) {
+	if err := b.registerCommands(ctx); err != nil {
+		l("discord bot: registering slash commands:", err)
+	}
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		resume, err := b.connectAndServe(ctx)
+		if err != nil {
+			l("discord bot: gateway connection lost:", err)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		wait := b.backoff.Duration()
+		l(fmt.Sprintf("discord bot: reconnecting in %s (resume=%v)", wait, resume))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// connectAndServe dials the gateway, identifies (or resumes), and services
+// the connection until it drops or ctx is cancelled. The returned bool
+// reports whether the drop is resumable.
+func (b *discordBot) connectAndServe(ctx context.Context) (resumable bool, err error) {
+	url := discordGatewayUrl
+	if b.resumeGateway != "" {
+		url = b.resumeGateway
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return false, err
+	}
+	b.mu.Lock()
+	b.conn = conn
+	b.mu.Unlock()
+	defer conn.Close()
+
+	var hello gatewayPayload
+	if err = conn.ReadJSON(&hello); err != nil {
+		return false, err
+	}
+	if hello.Op != gatewayOpHello {
+		return false, fmt.Errorf("expected hello, got op %d", hello.Op)
+	}
+	var h gatewayHello
+	if err = json.Unmarshal(hello.D, &h); err != nil {
+		return false, err
+	}
+	interval := time.Duration(h.HeartbeatInterval) * time.Millisecond
+
+	if b.sessionId != "" {
+		err = b.sendResume(conn)
+	} else {
+		err = b.sendIdentify(conn)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	hbCtx, cancelHb := context.WithCancel(ctx)
+	defer cancelHb()
+	go b.heartbeatLoop(hbCtx, conn, interval)
+
+	for {
+		var p gatewayPayload
+		if err = conn.ReadJSON(&p); err != nil {
+			return true, err
+		}
+		switch p.Op {
+		case gatewayOpDispatch:
+			b.seq.Store(int64(p.S))
+			b.handleDispatch(ctx, p)
+		case gatewayOpReconnect:
+			return true, fmt.Errorf("gateway requested reconnect")
+		case gatewayOpInvalidSession:
+			b.sessionId = ""
+			b.resumeGateway = ""
+			return false, fmt.Errorf("session invalidated")
+		case gatewayOpHeartbeatAck:
+			b.backoff.Reset()
+		}
+	}
+}
+
+// heartbeatLoop sends a heartbeat every interval and closes conn when a
+// heartbeat write fails, forcing a reconnect. Heartbeat acks are not
+// tracked here, so a connection that stays writable but stops acking is
+// not detected.
+func (b *discordBot) heartbeatLoop(ctx context.Context, conn *websocket.Conn, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			seq, _ := json.Marshal(b.seq.Load())
+			if err := conn.WriteJSON(gatewayPayload{Op: gatewayOpHeartbeat, D: seq}); err != nil {
+				_ = conn.Close() // zombie connection, the read loop will unblock and trigger a reconnect
+				return
+			}
+		}
+	}
+}
+
+func (b *discordBot) sendIdentify(conn *websocket.Conn) error {
+	d, _ := json.Marshal(map[string]any{
+		"token":   b.token,
+		"intents": 0,
+		"properties": map[string]string{
+			"os":      "linux",
+			"browser": "tenderduty",
+			"device":  "tenderduty",
+		},
+	})
+	return conn.WriteJSON(gatewayPayload{Op: gatewayOpIdentify, D: d})
+}
+
+func (b *discordBot) sendResume(conn *websocket.Conn) error {
+	d, _ := json.Marshal(map[string]any{
+		"token":      b.token,
+		"session_id": b.sessionId,
+		"seq":        b.seq.Load(),
+	})
+	return conn.WriteJSON(gatewayPayload{Op: gatewayOpResume, D: d})
+}
+
+func (b *discordBot) handleDispatch(ctx context.Context, p gatewayPayload) {
+	switch p.T {
+	case "READY":
+		var r gatewayReady
+		if err := json.Unmarshal(p.D, &r); err == nil {
+			b.sessionId = r.SessionId
+			b.backoff.Reset()
+		}
+	case "INTERACTION_CREATE":
+		var i discordInteraction
+		if err := json.Unmarshal(p.D, &i); err != nil {
+			l("discord bot: decoding interaction:", err)
+			return
+		}
+		go b.handleInteraction(ctx, i)
+	}
+}
+
+type discordInteraction struct {
+	Id    string `json:"id"`
+	Token string `json:"token"`
+	Data  struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+}
+
+func (i discordInteraction) option(name string) string {
+	for _, o := range i.Data.Options {
+		if o.Name == name {
+			return o.Value
+		}
+	}
+	return ""
+}
+
+// handleInteraction replies to a slash command with the same data already
+// reachable via getAlarms and currentAlarms; /ack and /silence mutate the
+// alarm bookkeeping in alert.go so notify.go's dispatcher stops re-firing.
+func (b *discordBot) handleInteraction(ctx context.Context, i discordInteraction) {
+	var reply string
+	switch i.Data.Name {
+	case "status":
+		chain := i.option("chain")
+		currentAlarmsMux.RLock()
+		hasAlarms := currentAlarms[chain] != nil
+		currentAlarmsMux.RUnlock()
+		if hasAlarms {
+			reply = getAlarms(chain)
+		}
+		if reply == "" {
+			reply = fmt.Sprintf("%s: no active alarms", chain)
+		}
+	case "alarms":
+		currentAlarmsMux.RLock()
+		for chain, alarms := range currentAlarms {
+			for alarm := range alarms {
+				reply += fmt.Sprintf("%s: %s\n", chain, alarm)
+			}
+		}
+		currentAlarmsMux.RUnlock()
+		if reply == "" {
+			reply = "no active alarms"
+		}
+	case "ack":
+		chain, alarm := i.option("chain"), i.option("alarm")
+		ackAlarm(chain, alarm)
+		reply = fmt.Sprintf("acknowledged %q on %s", alarm, chain)
+	case "silence":
+		chain := i.option("chain")
+		d, err := time.ParseDuration(i.option("duration"))
+		if err != nil {
+			reply = fmt.Sprintf("invalid duration: %s", err)
+			break
+		}
+		silenceChain(chain, d)
+		reply = fmt.Sprintf("silenced %s for %s", chain, d)
+	default:
+		reply = "unknown command"
+	}
+	if err := b.respond(ctx, i, reply); err != nil {
+		l("discord bot: replying to interaction:", err)
+	}
+}
+
+func (b *discordBot) respond(ctx context.Context, i discordInteraction, content string) error {
+	body, _ := json.Marshal(map[string]any{
+		"type": 4, // CHANNEL_MESSAGE_WITH_SOURCE
+		"data": map[string]string{"content": content},
+	})
+	route := fmt.Sprintf("/interactions/%s/%s/callback", i.Id, i.Token)
+	return b.restLimits.do(ctx, "POST", discordApiBase+route, body, "Bot "+b.token)
+}
+
+var discordSlashCommands = []map[string]any{
+	{"name": "status", "description": "show current alarm status for a chain", "options": []map[string]any{
+		{"name": "chain", "description": "chain name", "type": 3, "required": true},
+	}},
+	{"name": "alarms", "description": "list all active alarms across chains"},
+	{"name": "ack", "description": "acknowledge an alarm, suppressing re-notify until resolved", "options": []map[string]any{
+		{"name": "chain", "description": "chain name", "type": 3, "required": true},
+		{"name": "alarm", "description": "alarm text as shown by /alarms", "type": 3, "required": true},
+	}},
+	{"name": "silence", "description": "mute alerts for a chain for a duration", "options": []map[string]any{
+		{"name": "chain", "description": "chain name", "type": 3, "required": true},
+		{"name": "duration", "description": "e.g. 30m, 2h", "type": 3, "required": true},
+	}},
+}
+
+// discordApplicationId recovers the numeric application/client snowflake
+// that Discord's REST routes expect from a bot token. The token's first
+// dot-separated segment is the user id base64-encoded (no padding), not
+// the decimal id itself.
+func discordApplicationId(token string) (string, error) {
+	segment, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", fmt.Errorf("malformed bot token")
+	}
+	id, err := base64.RawStdEncoding.DecodeString(segment)
+	if err != nil {
+		return "", fmt.Errorf("decoding application id from token: %w", err)
+	}
+	return string(id), nil
+}
+
+func (b *discordBot) registerCommands(ctx context.Context) error {
+	appId, err := discordApplicationId(b.token)
+	if err != nil {
+		return err
+	}
+	route := fmt.Sprintf("/applications/%s/guilds/%s/commands", appId, b.guildId)
+	for _, cmd := range discordSlashCommands {
+		body, err := json.Marshal(cmd)
+		if err != nil {
+			return err
+		}
+		if err = b.restLimits.do(ctx, "POST", discordApiBase+route, body, "Bot "+b.token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// routeLimiter is a reactive rate limiter for the Discord REST API, which
+// returns a 429 with a Retry-After header per route rather than globally:
+// do() doesn't pre-empt requests, it just sleeps for Retry-After and
+// retries once a 429 comes back.
+type routeLimiter struct {
+	mu sync.Mutex
+}
+
+func newRouteLimiter() *routeLimiter {
+	return &routeLimiter{}
+}
+
+func (r *routeLimiter) do(ctx context.Context, method, url string, body []byte, auth string) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", auth)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retry, _ := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(retry * float64(time.Second))):
+		}
+		return r.do(ctx, method, url, body, auth)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("discord api %s returned %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package tenderduty
+
+import "testing"
+
+func TestDiscordApplicationId(t *testing.T) {
+	// "175928847299117063" base64-encoded (no padding), the shape of the
+	// first segment of a real bot token.
+	token := "MTc1OTI4ODQ3Mjk5MTE3MDYz.XXXXXX.YYYYYYYYYYYYYYYYYYYYYYYYYYY"
+	id, err := discordApplicationId(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "175928847299117063"; id != want {
+		t.Fatalf("discordApplicationId(%q) = %q, want %q", token, id, want)
+	}
+}
+
+func TestDiscordApplicationIdMalformed(t *testing.T) {
+	if _, err := discordApplicationId("not-a-token"); err == nil {
+		t.Fatal("expected an error for a token with no '.' segments")
+	}
+}
@@ -0,0 +1,48 @@
+package tenderduty
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+func init() {
+	notifyMux.register(emailAlerter{})
+}
+
+type emailAlerter struct{}
+
+func (emailAlerter) Name() string { return "email" }
+
+func (emailAlerter) Enabled(msg *alertMsg) bool {
+	return msg.mail
+}
+
+// Send delivers msg with smtp.SendMail, which takes no context: ctx's
+// deadline is not applied, and a slow SMTP server holds this call until
+// the connection itself fails.
+func (emailAlerter) Send(_ context.Context, msg *alertMsg) (err error) {
+	if len(msg.emailTo) == 0 {
+		return nil
+	}
+
+	prefix := "ALERT"
+	if msg.resolved {
+		prefix = "Resolved"
+	}
+	subject := fmt.Sprintf("tenderduty %s: %s", prefix, msg.chain)
+	body := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n",
+		msg.emailFrom, strings.Join(msg.emailTo, ","), subject, msg.message)
+
+	addr := fmt.Sprintf("%s:%d", msg.smtpHost, msg.smtpPort)
+	var auth smtp.Auth
+	if msg.smtpUser != "" {
+		auth = smtp.PlainAuth("", msg.smtpUser, msg.smtpPass, msg.smtpHost)
+	}
+	err = smtp.SendMail(addr, auth, msg.emailFrom, msg.emailTo, []byte(body))
+	if err != nil {
+		l("notify email:", err)
+	}
+	return err
+}
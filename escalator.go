@@ -0,0 +1,124 @@
+package tenderduty
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const escalationCheckInterval = 30 * time.Second
+
+// runEscalator periodically walks every active, unacknowledged alarm and
+// bumps it to the next tier of its AlertRule's escalation policy once
+// it's aged past that tier's After duration, e.g. "notify Telegram
+// immediately, escalate to PagerDuty if unresolved after 5 minutes."
+func (c *Config) runEscalator(ctx context.Context) {
+	t := time.NewTicker(escalationCheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			c.checkEscalations(ctx)
+		}
+	}
+}
+
+type dueEscalation struct {
+	chain, message, uniqueId string
+	tier                     int
+	step                     EscalationStep
+	rule                     *AlertRule
+}
+
+func (c *Config) checkEscalations(ctx context.Context) {
+	currentAlarmsMux.Lock()
+	var due []dueEscalation
+	for chain, alarms := range currentAlarms {
+		for message := range alarms {
+			if isAcked(chain, message) {
+				continue
+			}
+			meta := alarmMeta[chain][message]
+			if meta == nil {
+				continue
+			}
+			rule := c.resolveRule(chain, meta.alarmType)
+			if rule == nil || meta.tier >= len(rule.Escalation) {
+				continue
+			}
+			step := rule.Escalation[meta.tier]
+			if time.Since(meta.started) < step.After {
+				continue
+			}
+			meta.tier++
+			due = append(due, dueEscalation{chain: chain, message: message, uniqueId: meta.uniqueId, tier: meta.tier, step: step, rule: rule})
+		}
+	}
+	currentAlarmsMux.Unlock()
+
+	for _, d := range due {
+		l(fmt.Sprintf("⏫ escalating alarm on %s (%s) to %s after %s unacknowledged", d.chain, d.message, d.step.Severity, d.step.After))
+		c.escalate(ctx, d.chain, d.message, d.uniqueId, d.step, d.rule)
+	}
+}
+
+// escalate re-delivers an already-active alarm at the escalation step's
+// severity, restricted to the step's destinations, without touching
+// currentAlarms/alarmMeta bookkeeping since the alarm itself hasn't
+// changed state. uniqueId must be the same id the original alert fired
+// with (tracked in alarmMetaInfo), since PagerDuty's DedupKey and
+// Opsgenie's alias key off it: a different id per escalation would open
+// a new, orphaned incident instead of bumping the existing one, and
+// could collide across unrelated alarm types on the same chain. rule is
+// the AlertRule that produced step, so its Mentions (if any) carry
+// through to the escalated alert the same way they do on the initial one.
+func (c *Config) escalate(ctx context.Context, chainName, message, uniqueId string, step EscalationStep, rule *AlertRule) {
+	c.chainsMux.RLock()
+	a := &alertMsg{
+		pd:       routedTo("PagerDuty", &AlertRule{Destinations: step.Destinations}, false),
+		disc:     routedTo("Discord", &AlertRule{Destinations: step.Destinations}, false),
+		tg:       routedTo("Telegram", &AlertRule{Destinations: step.Destinations}, false),
+		slk:      routedTo("Slack", &AlertRule{Destinations: step.Destinations}, false),
+		ops:      routedTo("Opsgenie", &AlertRule{Destinations: step.Destinations}, false),
+		hook:     routedTo("webhook", &AlertRule{Destinations: step.Destinations}, false),
+		mail:     routedTo("email", &AlertRule{Destinations: step.Destinations}, false),
+		irc:      routedTo("IRC", &AlertRule{Destinations: step.Destinations}, false),
+		severity: step.Severity,
+		resolved: false,
+		chain:    chainName,
+		message:  message,
+		uniqueId: uniqueId,
+		key:      c.Pagerduty.ApiKey,
+
+		tgChannel:  c.Telegram.Channel,
+		tgKey:      c.Telegram.ApiKey,
+		tgMentions: strings.Join(mentionsFor(rule, c.Telegram.Mentions), " "),
+
+		discHook:     c.Discord.Webhook,
+		discMentions: strings.Join(mentionsFor(rule, c.Discord.Mentions), " "),
+
+		slackHook:     c.Slack.Webhook,
+		slackMentions: strings.Join(mentionsFor(rule, c.Slack.Mentions), " "),
+
+		opsgenieKey:    c.Opsgenie.ApiKey,
+		opsgenieApiUrl: c.Opsgenie.ApiUrl,
+
+		webhookUrl:      c.Webhook.Url,
+		webhookTemplate: c.Webhook.Template,
+
+		emailTo:   c.Email.To,
+		emailFrom: c.Email.From,
+		smtpHost:  c.Email.SmtpHost,
+		smtpPort:  c.Email.SmtpPort,
+		smtpUser:  c.Email.SmtpUser,
+		smtpPass:  c.Email.SmtpPass,
+
+		ircChannels: c.Chains[chainName].Alerts.IrcChannels,
+	}
+	c.applyTemplates(a, chainName, step.Severity, message, false)
+	c.chainsMux.RUnlock()
+	notifyMux.dispatch(ctx, a)
+}
@@ -0,0 +1,265 @@
+package tenderduty
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	irc "gopkg.in/sorcix/irc.v2"
+)
+
+func init() {
+	notifyMux.register(&ircAlerter{})
+}
+
+// IRC formatting codes, see https://modern.ircdocs.horse/formatting.html
+const (
+	ircBold   = "\x02"
+	ircColor  = "\x03"
+	ircUnderline = "\x1F"
+	ircReset  = "\x0F"
+
+	ircColorGreen  = "03"
+	ircColorRed    = "04"
+	ircColorOrange = "07"
+
+	// SASL result numerics, see https://ircv3.net/specs/extensions/sasl-3.1
+	ircRplSaslSuccess = "903"
+	ircErrSaslFail    = "904"
+)
+
+// ircAlerter fans alerts out to the alerting chain's IrcChannels, or to
+// every channel configured under Config.IRC when the chain names none,
+// reconnecting on drop, and answers !status/!alarms/!nodes from any
+// subscribed channel with the same data available via getAlarms.
+type ircAlerter struct {
+	mu       sync.Mutex
+	conn     net.Conn
+	reader   *bufio.Reader
+	channels []string
+}
+
+func (a *ircAlerter) Name() string { return "IRC" }
+
+func (a *ircAlerter) Enabled(msg *alertMsg) bool {
+	return msg.irc
+}
+
+// run dials the configured IRC network, authenticates, joins every channel
+// listed in cfg.Channels, and services PRIVMSG commands until ctx is
+// cancelled. Channels named only in a chain's Alerts.IrcChannels are not
+// joined. It's started once at startup, independently of
+// whether any particular chain has IRC alerts enabled.
+func (a *ircAlerter) run(ctx context.Context, cfg IRC) {
+	for ctx.Err() == nil {
+		if err := a.connectAndServe(ctx, cfg); err != nil {
+			l("irc:", err)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+func (a *ircAlerter) connectAndServe(ctx context.Context, cfg IRC) (err error) {
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if cfg.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", cfg.Server, &tls.Config{ServerName: strings.Split(cfg.Server, ":")[0]})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", cfg.Server)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	a.mu.Lock()
+	a.conn = conn
+	a.reader = bufio.NewReader(conn)
+	a.channels = cfg.Channels
+	a.mu.Unlock()
+
+	if cfg.SaslUser != "" {
+		a.send("CAP REQ :sasl")
+		m, rerr := a.waitFor(func(m *irc.Message) bool { return m.Command == "CAP" })
+		if rerr != nil {
+			return fmt.Errorf("irc: waiting for CAP ACK: %w", rerr)
+		}
+		if len(m.Params) < 3 || m.Params[1] != "ACK" || !strings.Contains(m.Params[2], "sasl") {
+			return fmt.Errorf("irc: server did not ack sasl capability: %v", m.Params)
+		}
+	}
+	a.send(fmt.Sprintf("NICK %s", cfg.Nick))
+	a.send(fmt.Sprintf("USER %s 0 * :tenderduty", cfg.Nick))
+	if cfg.SaslUser != "" {
+		a.send("AUTHENTICATE PLAIN")
+		if _, rerr := a.waitFor(func(m *irc.Message) bool {
+			return m.Command == "AUTHENTICATE" && len(m.Params) == 1 && m.Params[0] == "+"
+		}); rerr != nil {
+			return fmt.Errorf("irc: waiting for AUTHENTICATE continuation: %w", rerr)
+		}
+
+		payload := base64.StdEncoding.EncodeToString([]byte(cfg.SaslUser + "\x00" + cfg.SaslUser + "\x00" + cfg.SaslPass))
+		a.send("AUTHENTICATE " + payload)
+		m, rerr := a.waitFor(func(m *irc.Message) bool {
+			return m.Command == ircRplSaslSuccess || m.Command == ircErrSaslFail
+		})
+		if rerr != nil {
+			return fmt.Errorf("irc: waiting for sasl result: %w", rerr)
+		}
+		if m.Command == ircErrSaslFail {
+			return fmt.Errorf("irc: sasl authentication rejected: %v", m.Params)
+		}
+		a.send("CAP END")
+	}
+	for _, ch := range cfg.Channels {
+		a.send("JOIN " + ch)
+	}
+
+	for {
+		line, rerr := a.reader.ReadString('\n')
+		if rerr != nil {
+			return rerr
+		}
+		m := irc.ParseMessage(strings.TrimRight(line, "\r\n"))
+		if m == nil {
+			continue
+		}
+		switch m.Command {
+		case "PING":
+			a.send("PONG :" + strings.Join(m.Params, " "))
+		case "PRIVMSG":
+			if len(m.Params) == 2 {
+				a.handleCommand(m.Params[0], m.Params[1])
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// waitFor blocks reading lines from the connection until one parses into a
+// message accepted by match, returning it. It's only used during the SASL
+// handshake, before the main read loop in connectAndServe starts, so there's
+// no concurrent reader to race with.
+func (a *ircAlerter) waitFor(match func(*irc.Message) bool) (*irc.Message, error) {
+	for {
+		line, err := a.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		m := irc.ParseMessage(strings.TrimRight(line, "\r\n"))
+		if m == nil {
+			continue
+		}
+		if m.Command == "PING" {
+			a.send("PONG :" + strings.Join(m.Params, " "))
+			continue
+		}
+		if match(m) {
+			return m, nil
+		}
+	}
+}
+
+func (a *ircAlerter) send(line string) {
+	a.mu.Lock()
+	conn := a.conn
+	a.mu.Unlock()
+	if conn == nil {
+		return
+	}
+	_, _ = conn.Write([]byte(line + "\r\n"))
+}
+
+func (a *ircAlerter) privmsg(target, message string) {
+	a.send(fmt.Sprintf("PRIVMSG %s :%s", target, message))
+}
+
+// handleCommand answers !status, !alarms <chain>, and !nodes <chain> using
+// the same data reachable via getAlarms and each chain's node table.
+func (a *ircAlerter) handleCommand(channel, text string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return
+	}
+	switch fields[0] {
+	case "!status":
+		a.privmsg(channel, "tenderduty is running")
+	case "!alarms":
+		if len(fields) < 2 {
+			a.privmsg(channel, "usage: !alarms <chain>")
+			return
+		}
+		alarms := getAlarms(fields[1])
+		if alarms == "" {
+			alarms = fields[1] + ": no active alarms"
+		}
+		for _, line := range strings.Split(strings.TrimSpace(alarms), "\n") {
+			a.privmsg(channel, line)
+		}
+	case "!nodes":
+		if len(fields) < 2 {
+			a.privmsg(channel, "usage: !nodes <chain>")
+			return
+		}
+		cc := td.Chains[fields[1]]
+		if cc == nil {
+			a.privmsg(channel, "unknown chain "+fields[1])
+			return
+		}
+		for _, node := range cc.Nodes {
+			status := ircColor + ircColorGreen + "up" + ircReset
+			if node.down {
+				status = ircColor + ircColorRed + "down" + ircReset
+			}
+			a.privmsg(channel, fmt.Sprintf("%s: %s", node.Url, status))
+		}
+	}
+}
+
+// ircSeverityColor renders the standard mIRC color codes tenderduty uses
+// for alert lines: green once resolved, red for critical, orange/yellow
+// otherwise.
+func ircSeverityColor(msg *alertMsg) string {
+	if msg.resolved {
+		return ircColorGreen
+	}
+	if msg.severity == "critical" {
+		return ircColorRed
+	}
+	return ircColorOrange
+}
+
+func (a *ircAlerter) Send(_ context.Context, msg *alertMsg) error {
+	prefix := ircBold + "ALERT" + ircReset
+	if msg.resolved {
+		prefix = ircBold + "Resolved" + ircReset
+	}
+	line := fmt.Sprintf("%s%s%s %s: %s%s%s",
+		ircColor, ircSeverityColor(msg), prefix, msg.chain, ircUnderline, msg.message, ircReset)
+
+	channels := msg.ircChannels
+	if len(channels) == 0 {
+		a.mu.Lock()
+		channels = a.channels
+		a.mu.Unlock()
+	}
+	for _, ch := range channels {
+		a.privmsg(ch, line)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package tenderduty
+
+import (
+	"context"
+	"time"
+)
+
+// alertTimeout bounds how long a single provider gets to deliver one
+// alertMsg. It keeps a slow or unreachable destination from stalling the
+// alertChan consumer for every other provider.
+const alertTimeout = 30 * time.Second
+
+// dispatcher fans each alertMsg that arrives on alertChan out to every
+// registered Alerter, one goroutine per provider so they can't block each
+// other. Providers register themselves in their own init() so adding a new
+// destination never requires touching this file.
+type dispatcher struct {
+	providers []Alerter
+}
+
+// notifyMux is the single dispatcher instance every provider registers
+// with and every alertChan consumer sends through.
+var notifyMux = &dispatcher{}
+
+func (d *dispatcher) register(a Alerter) {
+	d.providers = append(d.providers, a)
+}
+
+// run drains c.alertChan until ctx is cancelled, handing each message to
+// every enabled provider concurrently.
+func (d *dispatcher) run(ctx context.Context, c *Config) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-c.alertChan:
+			d.dispatch(ctx, msg)
+		}
+	}
+}
+
+// dispatch hands msg to every enabled provider. The shouldNotify dedup
+// check runs here, once per provider, rather than inside each Send:
+// sendWithRetry may call Send several times for a single dispatch, and
+// shouldNotify's map mutates on every call, so checking it from inside
+// Send would mark the alert as sent on attempt 1 and silently swallow
+// every retry.
+func (d *dispatcher) dispatch(ctx context.Context, msg *alertMsg) {
+	for _, p := range d.providers {
+		if !p.Enabled(msg) {
+			continue
+		}
+		if !shouldNotify(msg, p.Name()) {
+			continue
+		}
+		go sendWithRetry(ctx, p, msg)
+	}
+}
@@ -0,0 +1,91 @@
+package tenderduty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+func init() {
+	notifyMux.register(opsgenieAlerter{})
+}
+
+const defaultOpsgenieApiUrl = "https://api.opsgenie.com/v2/alerts"
+
+type opsgenieAlerter struct{}
+
+func (opsgenieAlerter) Name() string { return "Opsgenie" }
+
+func (opsgenieAlerter) Enabled(msg *alertMsg) bool {
+	return msg.ops
+}
+
+type opsgenieCreateRequest struct {
+	Message     string   `json:"message"`
+	Alias       string   `json:"alias"`
+	Description string   `json:"description"`
+	Priority    string   `json:"priority,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// opsgeniePriority maps tenderduty's severity strings onto Opsgenie's
+// P1 (highest) .. P5 scale; anything unrecognized falls back to P3.
+func opsgeniePriority(severity string) string {
+	switch severity {
+	case "critical":
+		return "P2"
+	case "warning":
+		return "P3"
+	default:
+		return "P3"
+	}
+}
+
+// Send creates an Opsgenie alert keyed by alias == uniqueId so a later
+// resolve on the same uniqueId closes the existing alert instead of
+// opening a duplicate.
+func (opsgenieAlerter) Send(ctx context.Context, msg *alertMsg) (err error) {
+	apiUrl := msg.opsgenieApiUrl
+	if apiUrl == "" {
+		apiUrl = defaultOpsgenieApiUrl
+	}
+
+	var req *http.Request
+	if msg.resolved {
+		// uniqueId can be an arbitrary string (e.g. a node's RPC URL for a
+		// node-down alarm), so it must be escaped before it's spliced into
+		// the path or a value containing "/" or "?" breaks the request.
+		req, err = http.NewRequestWithContext(ctx, "POST",
+			fmt.Sprintf("%s/%s/close?identifierType=alias", apiUrl, url.PathEscape(msg.uniqueId)), nil)
+	} else {
+		var data []byte
+		data, err = json.Marshal(opsgenieCreateRequest{
+			Message:     fmt.Sprintf("%s: %s", msg.chain, msg.message),
+			Alias:       msg.uniqueId,
+			Description: msg.message,
+			Priority:    opsgeniePriority(msg.severity),
+			Tags:        []string{"tenderduty", msg.chain},
+		})
+		if err == nil {
+			req, err = http.NewRequestWithContext(ctx, "POST", apiUrl, bytes.NewBuffer(data))
+		}
+	}
+	if err != nil {
+		l("notify opsgenie:", err)
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+msg.opsgenieKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		l("notify opsgenie:", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
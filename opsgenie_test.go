@@ -0,0 +1,37 @@
+package tenderduty
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestOpsgenieSendEscapesUniqueId guards against a regression where a
+// node-down alarm's uniqueId (a full RPC URL) was spliced unescaped into
+// the resolve path, producing a malformed request that could never
+// close the alert.
+func TestOpsgenieSendEscapesUniqueId(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	msg := &alertMsg{
+		resolved:       true,
+		uniqueId:       "https://rpc.example.com:443",
+		opsgenieApiUrl: srv.URL,
+	}
+	if err := (opsgenieAlerter{}).Send(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantSuffix := "/" + url.PathEscape(msg.uniqueId) + "/close"
+	if !strings.HasSuffix(gotPath, wantSuffix) {
+		t.Fatalf("request path %q does not end with escaped uniqueId close route %q", gotPath, wantSuffix)
+	}
+}
@@ -0,0 +1,66 @@
+package tenderduty
+
+import (
+	"context"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+func init() {
+	notifyMux.register(pagerdutyAlerter{})
+}
+
+type pagerdutyAlerter struct{}
+
+func (pagerdutyAlerter) Name() string { return "PagerDuty" }
+
+func (pagerdutyAlerter) Enabled(msg *alertMsg) bool {
+	return msg.pd
+}
+
+func (pagerdutyAlerter) Send(ctx context.Context, msg *alertMsg) (err error) {
+	// key from the example, don't spam their api
+	if msg.key == "aaaaaaaaaaaabbbbbbbbbbbbbcccccccccccc" {
+		l("invalid pagerduty key")
+		return
+	}
+	action := "trigger"
+	if msg.resolved {
+		action = "resolve"
+	}
+	_, err = pagerduty.ManageEventWithContext(ctx, pagerduty.V2Event{
+		RoutingKey: msg.key,
+		Action:     action,
+		DedupKey:   msg.uniqueId,
+		Payload: &pagerduty.V2Payload{
+			Summary:       msg.message,
+			Source:        msg.uniqueId,
+			Severity:      msg.severity,
+			CustomDetails: pagerdutyDetails(msg.tmplData),
+		},
+	})
+	return
+}
+
+// pagerdutyDetails surfaces the same validator context the other
+// providers get through templates, as a plain map since PagerDuty's
+// custom_details is free-form JSON rather than a rendered string.
+func pagerdutyDetails(d TemplateData) map[string]any {
+	nodes := make([]string, 0, len(d.Nodes))
+	for _, n := range d.Nodes {
+		status := "up"
+		if n.Down {
+			status = "down"
+		}
+		nodes = append(nodes, n.Url+": "+status)
+	}
+	return map[string]any{
+		"chain_id":        d.ChainID,
+		"moniker":         d.Moniker,
+		"valcons":         d.Valcons,
+		"missed":          d.Missed,
+		"window":          d.Window,
+		"last_block_time": d.LastBlockTime,
+		"nodes":           nodes,
+	}
+}
@@ -0,0 +1,227 @@
+package tenderduty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	alertsSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tenderduty_alerts_sent_total",
+		Help: "outbound alert delivery attempts, by provider and result",
+	}, []string{"provider", "result"})
+	alertsRetryTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tenderduty_alerts_retry_total",
+		Help: "outbound alert delivery retries, by provider",
+	}, []string{"provider"})
+)
+
+// httpStatusError is returned by providers in place of a bare error so the
+// retry-aware sender can tell a rate limit (retryable, honoring
+// Retry-After) apart from a permanent 4xx (not worth retrying).
+type httpStatusError struct {
+	status     int
+	retryAfter time.Duration
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("http status %d", e.status)
+}
+
+func (e *httpStatusError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// checkResponse turns a non-2xx response into an *httpStatusError,
+// capturing Retry-After on a 429 so sendWithRetry can honor it.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	e := &httpStatusError{status: resp.StatusCode}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if v := resp.Header.Get("Retry-After"); v != "" {
+			if secs, err := strconv.ParseFloat(v, 64); err == nil {
+				e.retryAfter = time.Duration(secs * float64(time.Second))
+			}
+		}
+	}
+	return e
+}
+
+// tokenBucket is a minimal per-destination rate limiter: one token
+// refilled every `every`, up to `burst` banked.
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	burst  float64
+	every  time.Duration
+	last   time.Time
+}
+
+func newTokenBucket(every time.Duration, burst float64) *tokenBucket {
+	return &tokenBucket{tokens: burst, burst: burst, every: every, last: time.Now()}
+}
+
+func (t *tokenBucket) wait(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		now := time.Now()
+		elapsed := now.Sub(t.last)
+		t.tokens += elapsed.Seconds() / t.every.Seconds()
+		if t.tokens > t.burst {
+			t.tokens = t.burst
+		}
+		t.last = now
+		if t.tokens >= 1 {
+			t.tokens--
+			t.mu.Unlock()
+			return nil
+		}
+		t.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(t.every):
+		}
+	}
+}
+
+var (
+	limiterMux sync.Mutex
+	limiters   = make(map[string]*tokenBucket)
+)
+
+// limiterFor returns the shared token bucket for a provider, creating one
+// bounded to one request/second with a small burst on first use.
+func limiterFor(provider string) *tokenBucket {
+	limiterMux.Lock()
+	defer limiterMux.Unlock()
+	if limiters[provider] == nil {
+		limiters[provider] = newTokenBucket(time.Second, 5)
+	}
+	return limiters[provider]
+}
+
+// Backoff is a small exponential-backoff primitive modeled on dskit's, so
+// callers can ask "should I keep retrying" (Ongoing), sleep the next
+// interval (Wait), and, once done, tell why they stopped (Err/ErrCause).
+type Backoff struct {
+	cfg        BackoffConfig
+	ctx        context.Context
+	numRetries int
+	duration   time.Duration
+}
+
+type BackoffConfig struct {
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	MaxRetries int // 0 means unlimited, bounded only by ctx
+}
+
+func NewBackoff(ctx context.Context, cfg BackoffConfig) *Backoff {
+	return &Backoff{cfg: cfg, ctx: ctx, duration: cfg.MinBackoff}
+}
+
+func (b *Backoff) Reset() {
+	b.numRetries = 0
+	b.duration = b.cfg.MinBackoff
+}
+
+func (b *Backoff) Ongoing() bool {
+	return b.ctx.Err() == nil && (b.cfg.MaxRetries == 0 || b.numRetries < b.cfg.MaxRetries)
+}
+
+func (b *Backoff) NumRetries() int { return b.numRetries }
+
+// Err reports why Ongoing became false: nil if the caller is still going,
+// the context's error on cancellation, or errMaxRetries once exhausted.
+func (b *Backoff) Err() error {
+	if b.ctx.Err() != nil {
+		return b.ctx.Err()
+	}
+	if b.cfg.MaxRetries != 0 && b.numRetries >= b.cfg.MaxRetries {
+		return errMaxRetries
+	}
+	return nil
+}
+
+// ErrCause unwraps to the context's cancellation cause, if any, so a
+// caller can log *why* the context died instead of just that it did.
+func (b *Backoff) ErrCause() error {
+	return context.Cause(b.ctx)
+}
+
+func (b *Backoff) Wait() {
+	select {
+	case <-b.ctx.Done():
+	case <-time.After(b.duration):
+	}
+	b.numRetries++
+	b.duration *= 2
+	if b.duration > b.cfg.MaxBackoff {
+		b.duration = b.cfg.MaxBackoff
+	}
+}
+
+var errMaxRetries = errors.New("max retries exceeded")
+
+// sendWithRetry is the single choke point every alertMsg goes through on
+// its way to a provider: it rate-limits per destination, retries
+// transient failures with backoff (honoring Retry-After on a 429), and
+// records the outcome to the alerts_sent_total/alerts_retry_total
+// counters.
+func sendWithRetry(ctx context.Context, p Alerter, msg *alertMsg) {
+	limiter := limiterFor(p.Name())
+	bo := NewBackoff(ctx, BackoffConfig{
+		MinBackoff: time.Second,
+		MaxBackoff: 30 * time.Second,
+		MaxRetries: 5,
+	})
+
+	for bo.Ongoing() {
+		if err := limiter.wait(ctx); err != nil {
+			break
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, alertTimeout)
+		err := p.Send(attemptCtx, msg)
+		cancel()
+		if err == nil {
+			alertsSentTotal.WithLabelValues(p.Name(), "success").Inc()
+			return
+		}
+
+		var hse *httpStatusError
+		if errors.As(err, &hse) && !hse.retryable() {
+			l(fmt.Sprintf("notify %s: permanent failure:", p.Name()), err)
+			alertsSentTotal.WithLabelValues(p.Name(), "failure").Inc()
+			return
+		}
+
+		alertsRetryTotal.WithLabelValues(p.Name()).Inc()
+		l(fmt.Sprintf("notify %s: retrying after error:", p.Name()), err)
+		if hse != nil && hse.retryAfter > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(hse.retryAfter):
+			}
+			bo.numRetries++
+			continue
+		}
+		bo.Wait()
+	}
+
+	if err := bo.Err(); err != nil {
+		alertsSentTotal.WithLabelValues(p.Name(), "failure").Inc()
+		l(fmt.Sprintf("notify %s: giving up after %d retries: %s (cause: %v)", p.Name(), bo.NumRetries(), err, bo.ErrCause()))
+	}
+}
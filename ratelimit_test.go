@@ -0,0 +1,91 @@
+package tenderduty
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketWait(t *testing.T) {
+	b := newTokenBucket(50*time.Millisecond, 1)
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatalf("first wait: unexpected error: %v", err)
+	}
+	start := time.Now()
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatalf("second wait: unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("second wait returned after %s, expected to block for ~%s", elapsed, 50*time.Millisecond)
+	}
+}
+
+func TestTokenBucketWaitContextCancelled(t *testing.T) {
+	b := newTokenBucket(time.Minute, 1)
+	_ = b.wait(context.Background()) // drain the only token
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("wait on a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestBackoffOngoingAndErr(t *testing.T) {
+	bo := NewBackoff(context.Background(), BackoffConfig{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetries: 2})
+	if !bo.Ongoing() {
+		t.Fatal("expected Ongoing() before any retries")
+	}
+	bo.Wait()
+	bo.Wait()
+	if bo.Ongoing() {
+		t.Fatal("expected Ongoing() to be false after MaxRetries waits")
+	}
+	if !errors.Is(bo.Err(), errMaxRetries) {
+		t.Fatalf("Err() = %v, want errMaxRetries", bo.Err())
+	}
+}
+
+func TestBackoffErrCause(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("shutting down"))
+	bo := NewBackoff(ctx, BackoffConfig{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	if bo.Ongoing() {
+		t.Fatal("expected Ongoing() to be false once ctx is cancelled")
+	}
+	if bo.ErrCause() == nil {
+		t.Fatal("expected ErrCause() to report the cancellation cause")
+	}
+}
+
+// fakeAlerter fails its first `failures` Send calls, then succeeds.
+type fakeAlerter struct {
+	name     string
+	failures int
+	calls    int
+}
+
+func (f *fakeAlerter) Name() string           { return f.name }
+func (f *fakeAlerter) Enabled(*alertMsg) bool { return true }
+
+func (f *fakeAlerter) Send(context.Context, *alertMsg) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return &httpStatusError{status: 500}
+	}
+	return nil
+}
+
+// TestSendWithRetryRetriesSend guards against a regression where a
+// dedup check inside a provider's Send made retries appear to succeed
+// without ever actually delivering the message: Send must be invoked
+// once per attempt, and sendWithRetry must keep retrying a transient
+// failure until it succeeds.
+func TestSendWithRetryRetriesSend(t *testing.T) {
+	p := &fakeAlerter{name: "fake-retry", failures: 1}
+	sendWithRetry(context.Background(), p, &alertMsg{message: "m"})
+	if p.calls != 2 {
+		t.Fatalf("Send called %d times, want 2 (one failure, one success)", p.calls)
+	}
+}
@@ -0,0 +1,71 @@
+package tenderduty
+
+import "time"
+
+// AlertRule matches a {chain, alarm_type} pair and sets the severity its
+// alerts are raised at, a set of destinations and, optionally, an
+// escalation policy. Severity is not matched on. Chain and
+// AlarmType may be "*" to match anything, so operators can, for example,
+// send every alarm on "cosmoshub-4" to PagerDuty while routing every
+// chain's "consecutive_missed" warnings to Telegram only.
+type AlertRule struct {
+	Chain     string `yaml:"chain"`
+	AlarmType string `yaml:"alarm_type"`
+	Severity  string `yaml:"severity"`
+
+	// Destinations names providers by Alerter.Name(), e.g. "Telegram",
+	// "PagerDuty", "Slack". Empty means fall back to whatever the chain's
+	// existing per-provider Alerts.*Alerts flags enable.
+	Destinations []string         `yaml:"destinations"`
+	Mentions     []string         `yaml:"mentions"`
+	Escalation   []EscalationStep `yaml:"escalation"`
+}
+
+// EscalationStep bumps an unacknowledged alarm to a higher severity and a
+// (usually wider) set of destinations once it's been active for After.
+type EscalationStep struct {
+	After        time.Duration `yaml:"after"`
+	Severity     string        `yaml:"severity"`
+	Destinations []string      `yaml:"destinations"`
+}
+
+// alarm_type values raised by (cc *ChainConfig) watch. AlertRule.AlarmType
+// matches one of these, or "*" for any.
+const (
+	alarmTypeNoServers         = "no_servers"
+	alarmTypeStalled           = "stalled"
+	alarmTypeConsecutiveMissed = "consecutive_missed"
+	alarmTypePercentageWindow  = "percentage_window"
+	alarmTypeNodeDown          = "node_down"
+)
+
+// resolveRule returns the most specific AlertRule configured for
+// (chain, alarmType), preferring an exact chain+alarm_type match, then
+// chain-only, then alarm_type-only, then a catch-all "*"/"*" rule. It
+// returns nil if the operator hasn't configured any routing rules, in
+// which case callers should fall back to the legacy behavior of alerting
+// every enabled destination at "critical".
+func (c *Config) resolveRule(chain, alarmType string) *AlertRule {
+	var chainMatch, typeMatch, wildcard *AlertRule
+	for i := range c.AlertRules {
+		r := &c.AlertRules[i]
+		switch {
+		case r.Chain == chain && r.AlarmType == alarmType:
+			return r
+		case r.Chain == chain && r.AlarmType == "*":
+			chainMatch = r
+		case r.Chain == "*" && r.AlarmType == alarmType:
+			typeMatch = r
+		case r.Chain == "*" && r.AlarmType == "*":
+			wildcard = r
+		}
+	}
+	switch {
+	case chainMatch != nil:
+		return chainMatch
+	case typeMatch != nil:
+		return typeMatch
+	default:
+		return wildcard
+	}
+}
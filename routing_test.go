@@ -0,0 +1,76 @@
+package tenderduty
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveRulePrecedence(t *testing.T) {
+	chainType := AlertRule{Chain: "cosmoshub-4", AlarmType: "stalled", Severity: "exact"}
+	chainOnly := AlertRule{Chain: "cosmoshub-4", AlarmType: "*", Severity: "chain"}
+	typeOnly := AlertRule{Chain: "*", AlarmType: "stalled", Severity: "type"}
+	wildcard := AlertRule{Chain: "*", AlarmType: "*", Severity: "wildcard"}
+
+	c := &Config{AlertRules: []AlertRule{wildcard, typeOnly, chainOnly, chainType}}
+
+	if got := c.resolveRule("cosmoshub-4", "stalled"); got.Severity != "exact" {
+		t.Fatalf("exact chain+alarm_type match = %q, want %q", got.Severity, "exact")
+	}
+	if got := c.resolveRule("cosmoshub-4", "node_down"); got.Severity != "chain" {
+		t.Fatalf("chain-only match = %q, want %q", got.Severity, "chain")
+	}
+	if got := c.resolveRule("osmosis-1", "stalled"); got.Severity != "type" {
+		t.Fatalf("alarm_type-only match = %q, want %q", got.Severity, "type")
+	}
+	if got := c.resolveRule("osmosis-1", "node_down"); got.Severity != "wildcard" {
+		t.Fatalf("wildcard match = %q, want %q", got.Severity, "wildcard")
+	}
+}
+
+func TestResolveRuleNoRules(t *testing.T) {
+	c := &Config{}
+	if got := c.resolveRule("cosmoshub-4", "stalled"); got != nil {
+		t.Fatalf("resolveRule with no configured rules = %v, want nil", got)
+	}
+}
+
+func TestRoutedTo(t *testing.T) {
+	if !routedTo("Telegram", nil, true) {
+		t.Fatal("nil rule should fall back to def=true")
+	}
+	if routedTo("Telegram", nil, false) {
+		t.Fatal("nil rule should fall back to def=false")
+	}
+
+	empty := &AlertRule{}
+	if !routedTo("Telegram", empty, true) {
+		t.Fatal("rule with no Destinations should fall back to def")
+	}
+
+	explicit := &AlertRule{Destinations: []string{"PagerDuty", "Opsgenie"}}
+	if !routedTo("PagerDuty", explicit, false) {
+		t.Fatal("PagerDuty is listed in Destinations, should route regardless of def")
+	}
+	if routedTo("Telegram", explicit, true) {
+		t.Fatal("Telegram is not listed in Destinations, should not route even if def=true")
+	}
+}
+
+func TestMentionsFor(t *testing.T) {
+	def := []string{"@chain-oncall"}
+
+	if got := mentionsFor(nil, def); !reflect.DeepEqual(got, def) {
+		t.Fatalf("nil rule: mentionsFor() = %v, want %v", got, def)
+	}
+
+	empty := &AlertRule{}
+	if got := mentionsFor(empty, def); !reflect.DeepEqual(got, def) {
+		t.Fatalf("rule with no Mentions: mentionsFor() = %v, want %v", got, def)
+	}
+
+	ruleMentions := []string{"@rule-oncall"}
+	withMentions := &AlertRule{Mentions: ruleMentions}
+	if got := mentionsFor(withMentions, def); !reflect.DeepEqual(got, ruleMentions) {
+		t.Fatalf("rule with Mentions: mentionsFor() = %v, want %v", got, ruleMentions)
+	}
+}
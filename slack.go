@@ -0,0 +1,94 @@
+package tenderduty
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func init() {
+	notifyMux.register(slackAlerter{})
+}
+
+type slackAlerter struct{}
+
+func (slackAlerter) Name() string { return "Slack" }
+
+func (slackAlerter) Enabled(msg *alertMsg) bool {
+	return msg.slk
+}
+
+// slackColor mirrors templateSeverityColor, just expressed as a Slack
+// attachment color string instead of a Discord embed's numeric color.
+func slackColor(msg *alertMsg) string {
+	return fmt.Sprintf("#%06x", templateSeverityColor(msg.severity, msg.resolved))
+}
+
+type slackMessage struct {
+	Text        string            `json:"text,omitempty"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color,omitempty"`
+	Blocks []slackBlock `json:"blocks,omitempty"`
+}
+
+type slackBlock struct {
+	Type string     `json:"type"`
+	Text *slackText `json:"text,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func buildSlackMessage(msg *alertMsg) *slackMessage {
+	text, err := renderTemplate(msg.slackTemplate, defaultSlackTemplate, msg.tmplData)
+	if err != nil {
+		l("notify slack: template:", err)
+		text = msg.message
+	}
+	if msg.slackMentions != "" {
+		text += " " + msg.slackMentions
+	}
+	return &slackMessage{
+		Attachments: []slackAttachment{{
+			Color: slackColor(msg),
+			Blocks: []slackBlock{{
+				Type: "section",
+				Text: &slackText{
+					Type: "mrkdwn",
+					Text: text,
+				},
+			}},
+		}},
+	}
+}
+
+func (slackAlerter) Send(ctx context.Context, msg *alertMsg) (err error) {
+	data, err := json.Marshal(buildSlackMessage(msg))
+	if err != nil {
+		l("notify slack:", err)
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", msg.slackHook, bytes.NewBuffer(data))
+	if err != nil {
+		l("notify slack:", err)
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		l("notify slack:", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
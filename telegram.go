@@ -0,0 +1,79 @@
+package tenderduty
+
+import (
+	"context"
+	"html"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func init() {
+	notifyMux.register(telegramAlerter{})
+}
+
+var (
+	telegramBotsMux sync.Mutex
+	telegramBots    = make(map[string]*tgbotapi.BotAPI)
+)
+
+// telegramBotFor returns a cached *tgbotapi.BotAPI for key, constructing
+// one on first use. tgbotapi.NewBotAPI makes a getMe round-trip to
+// Telegram, so building a fresh client for every alert (and every retry
+// of every alert) would multiply that cost for no benefit.
+func telegramBotFor(key string) (*tgbotapi.BotAPI, error) {
+	telegramBotsMux.Lock()
+	defer telegramBotsMux.Unlock()
+	if bot := telegramBots[key]; bot != nil {
+		return bot, nil
+	}
+	bot, err := tgbotapi.NewBotAPI(key)
+	if err != nil {
+		return nil, err
+	}
+	telegramBots[key] = bot
+	return bot, nil
+}
+
+// telegramEscape HTML-escapes the TemplateData fields that can carry
+// attacker- or operator-controlled text (a node's reported moniker, a
+// relayed error string) before it reaches defaultTelegramTemplate (or any
+// override). Telegram messages are sent with ParseMode "HTML" below, but
+// TemplateData is filled in by text/template, which does no escaping of its
+// own, so a stray "<" or "&" would otherwise break Telegram's HTML parser
+// and silently drop the whole alert.
+func telegramEscape(data TemplateData) TemplateData {
+	data.Message = html.EscapeString(data.Message)
+	data.Moniker = html.EscapeString(data.Moniker)
+	return data
+}
+
+type telegramAlerter struct{}
+
+func (telegramAlerter) Name() string { return "Telegram" }
+
+func (telegramAlerter) Enabled(msg *alertMsg) bool {
+	return msg.tg
+}
+
+func (telegramAlerter) Send(ctx context.Context, msg *alertMsg) (err error) {
+	bot, err := telegramBotFor(msg.tgKey)
+	if err != nil {
+		l("notify telegram:", err)
+		return
+	}
+
+	text, err := renderTemplate(msg.tgTemplate, defaultTelegramTemplate, telegramEscape(msg.tmplData))
+	if err != nil {
+		l("notify telegram: template:", err)
+		return err
+	}
+
+	mc := tgbotapi.NewMessageToChannel(msg.tgChannel, text)
+	mc.ParseMode = "HTML"
+	_, err = bot.Send(mc)
+	if err != nil {
+		l("telegram send:", err)
+	}
+	return err
+}
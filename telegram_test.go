@@ -0,0 +1,22 @@
+package tenderduty
+
+import "testing"
+
+func TestTelegramEscape(t *testing.T) {
+	in := TemplateData{
+		Chain:   "cosmoshub-4",
+		Message: "node <http://evil> & co is down",
+		Moniker: "<b>spoofed</b>",
+	}
+	got := telegramEscape(in)
+
+	if want := "node &lt;http://evil&gt; &amp; co is down"; got.Message != want {
+		t.Fatalf("telegramEscape() Message = %q, want %q", got.Message, want)
+	}
+	if want := "&lt;b&gt;spoofed&lt;/b&gt;"; got.Moniker != want {
+		t.Fatalf("telegramEscape() Moniker = %q, want %q", got.Moniker, want)
+	}
+	if got.Chain != in.Chain {
+		t.Fatalf("telegramEscape() should not touch Chain, got %q", got.Chain)
+	}
+}
@@ -0,0 +1,111 @@
+package tenderduty
+
+import (
+	"bytes"
+	"text/template"
+	"time"
+)
+
+// TemplateData is what every alert template renders against, and what the
+// PagerDuty custom-details map is built from. It carries enough validator
+// context (moniker, valcons, the current miss window, node status) that a
+// custom template can link out to a block explorer or dashboard instead of
+// just repeating the plain-text message.
+type TemplateData struct {
+	Chain         string
+	ChainID       string
+	Moniker       string
+	Valcons       string
+	Missed        int64
+	Window        int64
+	LastBlockTime time.Time
+	Nodes         []TemplateNode
+	Severity      string
+	Resolved      bool
+	Message       string
+	TgMentions    string
+	DiscMentions  string
+}
+
+type TemplateNode struct {
+	Url  string
+	Down bool
+}
+
+const defaultDiscordTemplate = `{{if .Resolved}}💜 Resolved{{else}}🚨 ALERT{{end}}: {{.Message}} {{.DiscMentions}}`
+
+const defaultTelegramTemplate = `<b>{{.Chain}}</b>: {{if .Resolved}}💜 Resolved{{else}}🚨 ALERT{{end}} - {{.Message}} {{.TgMentions}}`
+
+const defaultSlackTemplate = `{{if .Resolved}}💜 Resolved{{else}}🚨 ALERT{{end}}: *{{.Chain}}* - {{.Message}}`
+
+// renderTemplate parses and executes tmplSrc against data, falling back to
+// def if tmplSrc is empty (a chain that hasn't overridden the default).
+func renderTemplate(tmplSrc, def string, data TemplateData) (string, error) {
+	if tmplSrc == "" {
+		tmplSrc = def
+	}
+	tmpl, err := template.New("alert").Parse(tmplSrc)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// applyTemplates fills in msg.tmplData from the chain's current state and
+// resolves which template each of Discord/Telegram/Slack should render,
+// preferring a per-chain override (ChainConfig.Alerts.Templates) over the
+// provider's config-level default.
+func (c *Config) applyTemplates(msg *alertMsg, chainName, severity, message string, resolved bool) {
+	msg.tmplData = TemplateData{
+		Chain:        chainName,
+		Severity:     severity,
+		Resolved:     resolved,
+		Message:      message,
+		TgMentions:   msg.tgMentions,
+		DiscMentions: msg.discMentions,
+	}
+
+	cc := c.Chains[chainName]
+	if cc != nil {
+		msg.tmplData.ChainID = cc.ChainId
+		msg.tmplData.LastBlockTime = cc.lastBlockTime
+		if cc.valInfo != nil {
+			msg.tmplData.Moniker = cc.valInfo.Moniker
+			msg.tmplData.Valcons = cc.valInfo.Valcons
+			msg.tmplData.Missed = cc.valInfo.Missed
+			msg.tmplData.Window = cc.valInfo.Window
+		}
+		for _, node := range cc.Nodes {
+			msg.tmplData.Nodes = append(msg.tmplData.Nodes, TemplateNode{Url: node.Url, Down: node.down})
+		}
+		msg.discTemplate = cc.Alerts.Templates["discord"]
+		msg.tgTemplate = cc.Alerts.Templates["telegram"]
+		msg.slackTemplate = cc.Alerts.Templates["slack"]
+	}
+	if msg.discTemplate == "" {
+		msg.discTemplate = c.Discord.Template
+	}
+	if msg.tgTemplate == "" {
+		msg.tgTemplate = c.Telegram.Template
+	}
+	if msg.slackTemplate == "" {
+		msg.slackTemplate = c.Slack.Template
+	}
+}
+
+// templateSeverityColor is the shared severity -> color mapping used by
+// every embed/attachment-based provider (Discord, Slack): purple once
+// resolved, red for critical, orange for anything else.
+func templateSeverityColor(severity string, resolved bool) uint {
+	if resolved {
+		return 0x7b2ff7
+	}
+	if severity == "critical" {
+		return 0xe01e5a
+	}
+	return 0xf2c744
+}
@@ -0,0 +1,47 @@
+package tenderduty
+
+import "testing"
+
+func TestRenderTemplateDefault(t *testing.T) {
+	got, err := renderTemplate("", "{{.Chain}}: {{.Message}}", TemplateData{Chain: "cosmoshub-4", Message: "stalled"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "cosmoshub-4: stalled"; got != want {
+		t.Fatalf("renderTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderTemplateOverride(t *testing.T) {
+	got, err := renderTemplate("custom: {{.Message}}", "{{.Chain}}: {{.Message}}", TemplateData{Chain: "cosmoshub-4", Message: "stalled"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "custom: stalled"; got != want {
+		t.Fatalf("renderTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderTemplateParseError(t *testing.T) {
+	if _, err := renderTemplate("{{.Unclosed", "", TemplateData{}); err == nil {
+		t.Fatal("expected a parse error for malformed template syntax")
+	}
+}
+
+func TestTemplateSeverityColor(t *testing.T) {
+	cases := []struct {
+		severity string
+		resolved bool
+		want     uint
+	}{
+		{"critical", false, 0xe01e5a},
+		{"warning", false, 0xf2c744},
+		{"critical", true, 0x7b2ff7},
+		{"warning", true, 0x7b2ff7},
+	}
+	for _, c := range cases {
+		if got := templateSeverityColor(c.severity, c.resolved); got != c.want {
+			t.Errorf("templateSeverityColor(%q, %v) = %#x, want %#x", c.severity, c.resolved, got, c.want)
+		}
+	}
+}
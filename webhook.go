@@ -0,0 +1,70 @@
+package tenderduty
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"text/template"
+)
+
+func init() {
+	notifyMux.register(webhookAlerter{})
+}
+
+type webhookAlerter struct{}
+
+func (webhookAlerter) Name() string { return "webhook" }
+
+func (webhookAlerter) Enabled(msg *alertMsg) bool {
+	return msg.hook
+}
+
+// defaultWebhookTemplate is used when Config.Webhook.Template is empty.
+const defaultWebhookTemplate = `{"chain":"{{.Chain}}","message":"{{.Message}}","severity":"{{.Severity}}","resolved":{{.Resolved}}}`
+
+type webhookPayload struct {
+	Chain    string
+	Message  string
+	Severity string
+	Resolved bool
+}
+
+func (webhookAlerter) Send(ctx context.Context, msg *alertMsg) (err error) {
+	tmplSrc := msg.webhookTemplate
+	if tmplSrc == "" {
+		tmplSrc = defaultWebhookTemplate
+	}
+	tmpl, err := template.New("webhook").Parse(tmplSrc)
+	if err != nil {
+		l("notify webhook: template:", err)
+		return err
+	}
+
+	var body bytes.Buffer
+	err = tmpl.Execute(&body, webhookPayload{
+		Chain:    msg.chain,
+		Message:  msg.message,
+		Severity: msg.severity,
+		Resolved: msg.resolved,
+	})
+	if err != nil {
+		l("notify webhook: template:", err)
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", msg.webhookUrl, &body)
+	if err != nil {
+		l("notify webhook:", err)
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		l("notify webhook:", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}